@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// Tokenizer estimates how many tokens a string will cost a given model.
+// Pluggable so tests can inject a deterministic counter instead of a real
+// (and possibly network-dependent) tokenizer.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// WhitespaceTokenizer is the fallback tokenizer for providers without a
+// well-known BPE vocabulary: it approximates one token per whitespace-
+// separated word.
+type WhitespaceTokenizer struct{}
+
+func (WhitespaceTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+// TiktokenTokenizer wraps tiktoken-go's BPE encoder for OpenAI-family models.
+type TiktokenTokenizer struct {
+	enc *tiktoken.Tiktoken
+}
+
+func NewTiktokenTokenizer(model string) (*TiktokenTokenizer, error) {
+	enc, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		enc, err = tiktoken.GetEncoding("cl100k_base")
+		if err != nil {
+			return nil, fmt.Errorf("loading tiktoken encoding: %w", err)
+		}
+	}
+	return &TiktokenTokenizer{enc: enc}, nil
+}
+
+func (t *TiktokenTokenizer) CountTokens(text string) int {
+	return len(t.enc.Encode(text, nil, nil))
+}
+
+// NewTokenizer picks tiktoken for the openai provider family and falls back
+// to the whitespace approximation for everything else.
+func NewTokenizer(providerName, model string) Tokenizer {
+	if providerName == "openai" {
+		if tok, err := NewTiktokenTokenizer(model); err == nil {
+			return tok
+		}
+	}
+	return WhitespaceTokenizer{}
+}
+
+// defaultContextTokens is the default used when --context-tokens is left at
+// zero. It is chosen by provider name only; the model name is not consulted,
+// so every model of one provider gets the same default.
+func defaultContextTokens(providerName string) int {
+	switch providerName {
+	case "openai":
+		return 128000
+	case "gemini":
+		return 1000000
+	case "glm":
+		return 128000
+	default: // ollama and anything unrecognized
+		return 8192
+	}
+}
+
+// ContextBudget decides what history a round's provider call actually sees,
+// trading off token-window pressure against debate continuity.
+type ContextBudget interface {
+	// Fit returns a history slice that fits the budget. It always pins the
+	// claim (history[0]) and the last full round (the final two messages);
+	// anything older is either kept verbatim (under budget) or condensed
+	// into a single rolling-summary message (over budget).
+	Fit(history []Message) ([]Message, error)
+}
+
+// Summarizer condenses older history into a short "prior context" blurb.
+// It is a function type (rather than requiring a full Provider) so tests
+// can inject a deterministic summarizer without standing up a fake provider.
+type Summarizer func(messages []Message) (string, error)
+
+// TokenBudget is the default ContextBudget: it counts tokens with Tokenizer
+// and, once they exceed MaxTokens*SummarizeThreshold, rolls the oldest
+// messages (excluding the pinned claim and last round) into one summary
+// message via Summarize.
+type TokenBudget struct {
+	Tokenizer          Tokenizer
+	MaxTokens          int
+	SummarizeThreshold float64 // fraction of MaxTokens that triggers condensing, e.g. 0.8
+	Summarize          Summarizer
+}
+
+func NewTokenBudget(tokenizer Tokenizer, maxTokens int, summarizeThreshold float64, summarize Summarizer) *TokenBudget {
+	return &TokenBudget{Tokenizer: tokenizer, MaxTokens: maxTokens, SummarizeThreshold: summarizeThreshold, Summarize: summarize}
+}
+
+const pinnedTailMessages = 2 // the last full round: one challenger + one defender message
+
+func (b *TokenBudget) Fit(history []Message) ([]Message, error) {
+	if len(history) <= 1+pinnedTailMessages {
+		return history, nil // nothing but the claim and/or last round to trim
+	}
+
+	total := 0
+	for _, m := range history {
+		total += b.Tokenizer.CountTokens(m.Content)
+	}
+	threshold := int(float64(b.MaxTokens) * b.SummarizeThreshold)
+	if total <= threshold {
+		return history, nil
+	}
+
+	claimEntry := history[0]
+	tail := history[len(history)-pinnedTailMessages:]
+	middle := history[1 : len(history)-pinnedTailMessages]
+	if len(middle) == 0 {
+		return history, nil
+	}
+
+	summaryText, err := b.Summarize(middle)
+	if err != nil {
+		return nil, fmt.Errorf("summarizing context: %w", err)
+	}
+
+	fitted := make([]Message, 0, 2+len(tail))
+	fitted = append(fitted, claimEntry, Message{Role: RoleSystem, Content: "Prior context: " + summaryText})
+	fitted = append(fitted, tail...)
+	return fitted, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// countingTokenizer is a deterministic stand-in for a real tokenizer: one
+// token per message content byte, so tests can precisely control when a
+// TokenBudget decides to condense.
+type countingTokenizer struct{}
+
+func (countingTokenizer) CountTokens(text string) int { return len(text) }
+
+func TestTokenBudget_UnderBudgetReturnsHistoryUnchanged(t *testing.T) {
+	budget := NewTokenBudget(countingTokenizer{}, 1000, 0.8, nil)
+	history := []Message{
+		{Role: RoleUser, Content: "claim"},
+		{Role: RoleChallenger, Content: "c1"},
+		{Role: RoleDefender, Content: "d1"},
+		{Role: RoleChallenger, Content: "c2"},
+		{Role: RoleDefender, Content: "d2"},
+	}
+	out, err := budget.Fit(history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != len(history) {
+		t.Fatalf("expected history untouched, got %d messages", len(out))
+	}
+}
+
+func TestTokenBudget_OverBudgetCondensesMiddle(t *testing.T) {
+	var summarizedWith []Message
+	budget := NewTokenBudget(countingTokenizer{}, 10, 0.5, func(messages []Message) (string, error) {
+		summarizedWith = messages
+		return "condensed", nil
+	})
+	history := []Message{
+		{Role: RoleUser, Content: "claim"},
+		{Role: RoleChallenger, Content: "c1-long-message"},
+		{Role: RoleDefender, Content: "d1-long-message"},
+		{Role: RoleChallenger, Content: "c2"},
+		{Role: RoleDefender, Content: "d2"},
+	}
+	out, err := budget.Fit(history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// claim + summary + pinned last round (c2, d2)
+	if len(out) != 4 {
+		t.Fatalf("expected 4 messages after condensing, got %d: %#v", len(out), out)
+	}
+	if out[0].Content != "claim" {
+		t.Fatalf("expected claim pinned first, got %+v", out[0])
+	}
+	if out[1].Role != RoleSystem || out[1].Content != "Prior context: condensed" {
+		t.Fatalf("unexpected summary message: %+v", out[1])
+	}
+	if out[2].Content != "c2" || out[3].Content != "d2" {
+		t.Fatalf("expected last round pinned at the tail, got %+v / %+v", out[2], out[3])
+	}
+	if len(summarizedWith) != 2 || summarizedWith[0].Content != "c1-long-message" {
+		t.Fatalf("summarizer called with unexpected middle slice: %#v", summarizedWith)
+	}
+}
+
+func TestTokenBudget_SummarizeErrorPropagates(t *testing.T) {
+	budget := NewTokenBudget(countingTokenizer{}, 10, 0.5, func(messages []Message) (string, error) {
+		return "", errors.New("boom")
+	})
+	history := []Message{
+		{Role: RoleUser, Content: "claim"},
+		{Role: RoleChallenger, Content: "c1-long-message"},
+		{Role: RoleDefender, Content: "d1-long-message"},
+		{Role: RoleChallenger, Content: "c2"},
+		{Role: RoleDefender, Content: "d2"},
+	}
+	if _, err := budget.Fit(history); err == nil {
+		t.Fatalf("expected error to propagate from summarizer")
+	}
+}
+
+func TestTokenBudget_ShortHistorySkipsCondensing(t *testing.T) {
+	budget := NewTokenBudget(countingTokenizer{}, 1, 0.1, func([]Message) (string, error) {
+		t.Fatalf("summarizer should not be called for short history")
+		return "", nil
+	})
+	history := []Message{{Role: RoleUser, Content: "claim"}, {Role: RoleChallenger, Content: "c1"}, {Role: RoleDefender, Content: "d1"}}
+	out, err := budget.Fit(history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("expected untouched history, got %d", len(out))
+	}
+}
+
+func TestNewTokenizer_NonOpenAIFallsBackToWhitespace(t *testing.T) {
+	tok := NewTokenizer("ollama", "llama3")
+	if _, ok := tok.(WhitespaceTokenizer); !ok {
+		t.Fatalf("expected WhitespaceTokenizer fallback, got %T", tok)
+	}
+}
+
+func TestDefaultContextTokens(t *testing.T) {
+	if defaultContextTokens("openai") != 128000 {
+		t.Fatalf("unexpected openai default")
+	}
+	if defaultContextTokens("unknown") != 8192 {
+		t.Fatalf("unexpected fallback default")
+	}
+}
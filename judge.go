@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RoleJudge identifies the judge's own messages in a shared history, the
+// same way RoleChallenger/RoleDefender do for the debaters.
+const RoleJudge = "judge"
+
+const defaultJudgePrompt = `You are the Judge. Score the Challenger and the Defender for this round on a 1-5 scale for each of: evidence, logic, novelty, concession. Also write a short free-text critique.
+Respond with ONLY a JSON object of this exact shape, no markdown fences, no commentary:
+{"challenger":{"evidence":N,"logic":N,"novelty":N,"concession":N},"defender":{"evidence":N,"logic":N,"novelty":N,"concession":N},"critique":"..."}`
+
+// SideScore is one side's rubric score for a single round.
+type SideScore struct {
+	Evidence   int `json:"evidence"`
+	Logic      int `json:"logic"`
+	Novelty    int `json:"novelty"`
+	Concession int `json:"concession"`
+}
+
+// Total sums the rubric for a quick per-round or cumulative comparison.
+func (s SideScore) Total() int {
+	return s.Evidence + s.Logic + s.Novelty + s.Concession
+}
+
+// Judgment is the judge's parsed verdict for a single round.
+type Judgment struct {
+	Challenger SideScore `json:"challenger"`
+	Defender   SideScore `json:"defender"`
+	Critique   string    `json:"critique"`
+}
+
+// judgeTally accumulates rubric totals across rounds so the judge can be
+// given running context and the final summary can declare a rolling winner.
+type judgeTally struct {
+	ChallengerTotal int
+	DefenderTotal   int
+	Rounds          int
+}
+
+func (t *judgeTally) add(j Judgment) {
+	t.ChallengerTotal += j.Challenger.Total()
+	t.DefenderTotal += j.Defender.Total()
+	t.Rounds++
+}
+
+// tallyFromTranscript rebuilds a judgeTally from already-judged rounds, so a
+// resumed run keeps accurate running totals.
+func tallyFromTranscript(t *Transcript) judgeTally {
+	var tally judgeTally
+	for _, r := range t.Rounds {
+		if r.Judgment != nil {
+			tally.add(*r.Judgment)
+		}
+	}
+	return tally
+}
+
+func (t judgeTally) rollingWinner() string {
+	switch {
+	case t.ChallengerTotal > t.DefenderTotal:
+		return RoleChallenger
+	case t.DefenderTotal > t.ChallengerTotal:
+		return RoleDefender
+	default:
+		return "tie"
+	}
+}
+
+// runJudge scores one round. It asks the judge provider for strict JSON and,
+// on a parse failure, retries once with a repair instruction before giving up.
+func runJudge(provider Provider, model, judgePrompt string, roundNum int, chal, def string, tally judgeTally) (Judgment, error) {
+	history := []Message{
+		{Role: RoleUser, Content: fmt.Sprintf(
+			"Round %d\nChallenger: %s\nDefender: %s\n\nRunning totals before this round - Challenger: %d, Defender: %d.",
+			roundNum, chal, def, tally.ChallengerTotal, tally.DefenderTotal,
+		)},
+	}
+
+	raw, err := runSingleRound(provider, model, judgePrompt, RoleJudge, history)
+	if err != nil {
+		return Judgment{}, fmt.Errorf("running judge: %w", err)
+	}
+
+	judgment, err := parseJudgment(raw)
+	if err == nil {
+		return judgment, nil
+	}
+
+	history = append(history, Message{Role: RoleJudge, Content: raw})
+	history = append(history, Message{Role: RoleUser, Content: fmt.Sprintf(
+		"That was not valid JSON (%v). Reply again with ONLY the JSON object described in the instructions.", err,
+	)})
+	raw, err = runSingleRound(provider, model, judgePrompt, RoleJudge, history)
+	if err != nil {
+		return Judgment{}, fmt.Errorf("running judge repair: %w", err)
+	}
+	return parseJudgment(raw)
+}
+
+// parseJudgment extracts a Judgment from the judge's raw text, tolerating a
+// stray markdown code fence around the JSON object.
+func parseJudgment(raw string) (Judgment, error) {
+	clean := strings.TrimSpace(raw)
+	clean = strings.TrimPrefix(clean, "```json")
+	clean = strings.TrimPrefix(clean, "```")
+	clean = strings.TrimSuffix(clean, "```")
+	clean = strings.TrimSpace(clean)
+
+	var j Judgment
+	if err := json.Unmarshal([]byte(clean), &j); err != nil {
+		return Judgment{}, fmt.Errorf("parsing judge response: %w", err)
+	}
+	return j, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestParseJudgment_PlainAndFenced(t *testing.T) {
+	raw := `{"challenger":{"evidence":4,"logic":3,"novelty":2,"concession":1},"defender":{"evidence":2,"logic":2,"novelty":2,"concession":2},"critique":"ok"}`
+	j, err := parseJudgment(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if j.Challenger.Total() != 10 || j.Defender.Total() != 8 {
+		t.Fatalf("unexpected totals: %+v", j)
+	}
+
+	fenced := "```json\n" + raw + "\n```"
+	j2, err := parseJudgment(fenced)
+	if err != nil {
+		t.Fatalf("unexpected error for fenced input: %v", err)
+	}
+	if j2 != j {
+		t.Fatalf("fenced parse mismatch: %+v vs %+v", j2, j)
+	}
+}
+
+func TestParseJudgment_Invalid(t *testing.T) {
+	if _, err := parseJudgment("not json"); err == nil {
+		t.Fatalf("expected error for invalid json")
+	}
+}
+
+func TestJudgeTally_RollingWinner(t *testing.T) {
+	var tally judgeTally
+	tally.add(Judgment{Challenger: SideScore{Evidence: 5, Logic: 5, Novelty: 5, Concession: 5}, Defender: SideScore{Evidence: 1, Logic: 1, Novelty: 1, Concession: 1}})
+	if tally.rollingWinner() != RoleChallenger {
+		t.Fatalf("expected challenger to be winning, got %s", tally.rollingWinner())
+	}
+	tally.add(Judgment{Challenger: SideScore{}, Defender: SideScore{Evidence: 3, Logic: 3, Novelty: 3, Concession: 3}})
+	if tally.rollingWinner() != RoleChallenger {
+		t.Fatalf("expected challenger still ahead after round 2, got %s", tally.rollingWinner())
+	}
+}
+
+func TestRunJudge_RetriesOnInvalidJSON(t *testing.T) {
+	calls := 0
+	fake := &fakeProvider{respond: func(model, system string, history []Message) (string, error) {
+		calls++
+		if calls == 1 {
+			return "not valid json", nil
+		}
+		return `{"challenger":{"evidence":3,"logic":3,"novelty":3,"concession":3},"defender":{"evidence":1,"logic":1,"novelty":1,"concession":1},"critique":"fixed"}`, nil
+	}}
+	j, err := runJudge(fake, "judge-model", defaultJudgePrompt, 1, "chal text", "def text", judgeTally{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected one retry (2 calls), got %d", calls)
+	}
+	if j.Critique != "fixed" {
+		t.Fatalf("unexpected judgment: %+v", j)
+	}
+}
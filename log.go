@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// LogConfig configures the CLI's structured logger: where it writes and how
+// verbose and how it's formatted.
+type LogConfig struct {
+	Format string // "text" or "json"
+	Level  string // "debug", "info", "warn", or "error"
+	File   string // "" means stderr
+}
+
+// logger is the process-wide structured logger. initLogger reconfigures it
+// once flags have been parsed; until then it defaults to a plain text
+// handler on stderr so early failures still produce readable output.
+var logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// initLogger builds the process-wide logger from CLI flags. The returned
+// io.Closer must be closed (flushing and closing any log file) before the
+// process exits.
+func initLogger(cfg LogConfig) (io.Closer, error) {
+	level, err := parseLogLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var w io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+	if cfg.File != "" {
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening log file: %w", err)
+		}
+		w = f
+		closer = f
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch cfg.Format {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text", "":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		_ = closer.Close()
+		return nil, fmt.Errorf("invalid --log-format %q: must be text or json", cfg.Format)
+	}
+
+	logger = slog.New(handler)
+	return closer, nil
+}
+
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid --log-level %q: must be debug, info, warn, or error", level)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitLogger_InvalidLevel(t *testing.T) {
+	if _, err := initLogger(LogConfig{Level: "not-a-level"}); err == nil {
+		t.Fatalf("expected error for invalid log level")
+	}
+}
+
+func TestInitLogger_InvalidFormat(t *testing.T) {
+	if _, err := initLogger(LogConfig{Format: "yaml"}); err == nil {
+		t.Fatalf("expected error for invalid log format")
+	}
+}
+
+func TestInitLogger_WritesJSONToFile(t *testing.T) {
+	original := logger
+	defer func() { logger = original }()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "debate.log")
+
+	closer, err := initLogger(LogConfig{Format: "json", Level: "info", File: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger.Info("hello", "round", 1)
+	if err := closer.Close(); err != nil {
+		t.Fatalf("unexpected error closing log file: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log file: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatalf("expected log output, got empty file")
+	}
+}
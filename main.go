@@ -8,7 +8,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/ollama/ollama/api"
 	"github.com/spf13/cobra"
 	// progressbar
 	"github.com/schollz/progressbar/v3"
@@ -20,8 +19,6 @@ const (
 	RoleChallenger = "challenger"
 	RoleDefender   = "defender"
 	RoleSummarizer = "summarizer"
-
-	MaxHistory = 100 // max number of previous messages to keep
 )
 
 type Message struct {
@@ -29,28 +26,30 @@ type Message struct {
 	Content string
 }
 
-type DebateRound struct {
-	Challenger string
-	Defender   string
-}
-
 type DebateConfig struct {
-	Rounds     int
-	Duration   string
-	InputFile  string
-	OutputFile string
+	Rounds           int
+	Duration         string
+	InputFile        string
+	OutputFile       string
+	Resume           bool
+	TranscriptFormat string // "jsonl", "markdown", or "both"
 }
 
 type LLMConfig struct {
-	ChallengerModel string
-	DefenderModel   string
-	ChalPrompt      string
-	DefPrompt       string
-}
-
-// Interface for LLM client to allow mocking
-type LLMClient interface {
-	Generate(ctx context.Context, model, system, prompt string) (string, error)
+	ChallengerProvider Provider
+	ChallengerModel    string
+	DefenderProvider   Provider
+	DefenderModel      string
+	ChalPrompt         string
+	DefPrompt          string
+
+	// JudgeProvider is nil when judging is disabled (the --judge flag was
+	// not set).
+	JudgeProvider Provider
+	JudgeModel    string
+	JudgePrompt   string
+
+	ContextBudget ContextBudget
 }
 
 // ---------------- Main -----------------
@@ -68,6 +67,7 @@ func run() error {
 		RunE:  runCLI,
 	}
 	addFlags(cmd)
+	cmd.AddCommand(newSummarizeCmd())
 	return cmd.Execute()
 }
 
@@ -76,14 +76,65 @@ func addFlags(cmd *cobra.Command) {
 	cmd.Flags().String("output", "", "Path to output report")
 	cmd.Flags().Int("rounds", 0, "Number of debate rounds")
 	cmd.Flags().String("duration", "", "Total duration (e.g., 1h)")
-	cmd.Flags().String("challenger", "llama3", "Challenger model")
-	cmd.Flags().String("defender", "llama3", "Defender model")
+	cmd.Flags().String("challenger", "ollama/llama3", "Challenger model, as provider/model (e.g. openai/gpt-4o-mini)")
+	cmd.Flags().String("defender", "ollama/llama3", "Defender model, as provider/model (e.g. gemini/gemini-1.5-pro)")
 	cmd.Flags().String("challenger-prompt", "", "Challenger prompt or file")
 	cmd.Flags().String("defender-prompt", "", "Defender prompt or file")
+	cmd.Flags().Bool("resume", false, "Resume from an existing <output>.jsonl transcript checkpoint")
+	cmd.Flags().String("transcript-format", "jsonl", "Transcript output format: jsonl, markdown, or both")
+	cmd.Flags().String("judge", "", "Judge model as provider/model (e.g. openai/gpt-4o); omit to disable judging")
+	cmd.Flags().String("judge-prompt", "", "Judge rubric prompt or file")
+	cmd.Flags().Int("context-tokens", 0, "Token budget for round history (default: per challenger provider)")
+	cmd.Flags().Float64("summarize-threshold", 0.8, "Fraction of the token budget that triggers rolling-summary condensing")
+	cmd.Flags().String("log-format", "text", "Log output format: text or json")
+	cmd.Flags().String("log-level", "info", "Log level: debug, info, warn, or error")
+	cmd.Flags().String("log-file", "", "Write logs to this file instead of stderr")
+}
+
+// newSummarizeCmd adds the "llmdebate summarize <transcript>" subcommand,
+// which re-derives the final report from a transcript JSONL file so a
+// crashed or interrupted run can still be summarized.
+func newSummarizeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "summarize <transcript>",
+		Short: "Summarize a debate transcript JSONL file",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runSummarizeCLI,
+	}
+}
+
+func runSummarizeCLI(_ *cobra.Command, args []string) error {
+	path := args[0]
+	t, err := loadTranscript(path)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("transcript not found: %s", path)
+	}
+	provider, err := NewProvider(t.LLM.ChallengerProvider)
+	if err != nil {
+		return fmt.Errorf("recreating challenger provider: %w", err)
+	}
+	report, err := summarizeDebate(provider, t, t.LLM.ChallengerModel)
+	if err != nil {
+		return err
+	}
+	fmt.Println(report)
+	return nil
 }
 
 // ---------------- CLI -----------------
 func runCLI(cmd *cobra.Command, _ []string) error {
+	logFormat, _ := cmd.Flags().GetString("log-format")
+	logLevel, _ := cmd.Flags().GetString("log-level")
+	logFile, _ := cmd.Flags().GetString("log-file")
+	logCloser, err := initLogger(LogConfig{Format: logFormat, Level: logLevel, File: logFile})
+	if err != nil {
+		return err
+	}
+	defer logCloser.Close()
+
 	config, llm, err := parseFlags(cmd)
 	if err != nil {
 		return err
@@ -98,15 +149,8 @@ func runCLI(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	client, err := api.ClientFromEnvironment()
-	if err != nil {
-		return fmt.Errorf("creating client: %w", err)
-	}
-
-	llmClient := NewOllamaClient(client)
-
 	if config.Rounds == 0 && config.Duration != "" {
-		config.Rounds, err = estimateRounds(llmClient, input, llm, config.Duration)
+		config.Rounds, err = estimateRounds(input, llm, config.Duration)
 		if err != nil {
 			return err
 		}
@@ -116,12 +160,19 @@ func runCLI(cmd *cobra.Command, _ []string) error {
 		config.Rounds = math.MaxInt
 	}
 
-	debate, err := runDebateFlow(llmClient, input, config, llm)
+	transcript, err := runDebateFlow(input, config, llm)
 	if err != nil {
 		return err
 	}
 
-	report, err := summarizeDebate(llmClient, debate, llm.ChallengerModel)
+	if config.TranscriptFormat == "markdown" || config.TranscriptFormat == "both" {
+		mdPath := config.OutputFile + ".transcript.md"
+		if err := os.WriteFile(mdPath, []byte(renderTranscriptMarkdown(transcript)), 0o644); err != nil {
+			return fmt.Errorf("writing markdown transcript: %w", err)
+		}
+	}
+
+	report, err := summarizeDebate(llm.ChallengerProvider, transcript, llm.ChallengerModel)
 	if err != nil {
 		return err
 	}
@@ -129,148 +180,193 @@ func runCLI(cmd *cobra.Command, _ []string) error {
 	return os.WriteFile(config.OutputFile, []byte(report), 0o644)
 }
 
-// ---------------- Ollama wrapper -----------------
-type OllamaClient struct {
-	Client *api.Client
-}
-
-func NewOllamaClient(client *api.Client) *OllamaClient {
-	return &OllamaClient{Client: client}
-}
+// ---------------- Debate -----------------
+func runDebateFlow(claim string, config DebateConfig, llm LLMConfig) (*Transcript, error) {
+	claimEntry := Message{Role: RoleUser, Content: claim}
+	history := []Message{claimEntry}
 
-func (o *OllamaClient) GenerateWithChannel(ctx context.Context, model, system, prompt string, channel chan<- int) (string, error) {
-	var result string
-
-	resultCount := 0
-	stream := true
-
-	err := o.Client.Generate(ctx, &api.GenerateRequest{
-		Model:  model,
-		System: system,
-		Prompt: prompt,
-		Stream: &stream,
-		Options: map[string]any{
-			"temperature": 0.7,
-			"top_p":       0.9,
-			"max_tokens":  150,
-		},
-	}, func(resp api.GenerateResponse) error {
-		result += resp.Response
-		channel <- resultCount
-		resultCount++
-		return nil
-	})
-	if err != nil {
-		close(channel)
-		return "", err
-	}
-	if result == "" {
-		close(channel)
-		return "", errors.New("empty response")
+	var checkpointPath string
+	if config.OutputFile != "" {
+		checkpointPath = transcriptPath(config.OutputFile)
 	}
 
-	close(channel)
-	return result, nil
-}
+	transcript := &Transcript{Claim: claim, LLM: transcriptLLMConfig(llm)}
+	startRound := 0
+	tally := judgeTally{}
 
-func (o *OllamaClient) Generate(ctx context.Context, model, system, prompt string) (string, error) {
-	channel := make(chan int)
-	progress := progressbar.Default(-1)
-	go func() {
-		for range channel {
-			err := progress.Add(1)
-			if err != nil {
-				err = fmt.Errorf("adding progress bar: %w", err)
-				panic(err)
-			}
-		}
-		err := progress.Finish()
+	if config.Resume && checkpointPath != "" {
+		loaded, err := loadTranscript(checkpointPath)
 		if err != nil {
-			err = fmt.Errorf("finishing progress bar: %w", err)
-			panic(err)
+			return nil, fmt.Errorf("loading transcript checkpoint: %w", err)
+		}
+		if loaded != nil {
+			if mismatch := llmConfigMismatch(loaded.LLM, transcript.LLM); mismatch != "" {
+				return nil, fmt.Errorf("cannot resume %s: %s", checkpointPath, mismatch)
+			}
+			transcript = loaded
+			history = transcriptHistory(transcript)
+			startRound = len(transcript.Rounds)
+			tally = tallyFromTranscript(transcript)
+			logger.Info("resuming debate from checkpoint", "completed_rounds", startRound)
 		}
-	}()
-	return o.GenerateWithChannel(ctx, model, system, prompt, channel)
-}
-
-func logDebate(format string, a ...any) (int, error) {
-	return fmt.Printf("\n Logged on: "+time.Now().String()+"\n"+format+"\n", a...)
-}
-
-// ---------------- Debate -----------------
-func runDebateFlow(client LLMClient, claim string, config DebateConfig, llm LLMConfig) ([]DebateRound, error) {
-	var rounds []DebateRound
-	claimEntry := Message{Role: RoleUser, Content: claim}
-	history := []Message{claimEntry}
-
-	_, err := logDebate("Starting debate with claim: %s\n", claim)
-	if err != nil {
-		return nil, err
 	}
 
-	for i := 0; i < config.Rounds; i++ {
-		tempHistory := trimHistory(history, MaxHistory+1)
-		tempHistory[0] = claimEntry
+	logger.Info("starting debate", "claim", claim)
+
+	for i := startRound; i < config.Rounds; i++ {
+		fitted, err := llm.ContextBudget.Fit(history)
+		if err != nil {
+			return nil, fmt.Errorf("fitting context budget: %w", err)
+		}
+		// Persist the fitted (possibly condensed) backbone itself, not just this
+		// round's copy, so a later round's Fit condenses on top of the previous
+		// rolling summary instead of re-summarizing an ever-growing raw history.
+		history = fitted
+		tempHistory := append([]Message(nil), history...)
 
 		var localHistory []Message
 
-		chal, err := runSingleRound(client, llm.ChallengerModel, RoleChallenger+"\n"+llm.ChalPrompt, tempHistory)
+		chalStart := time.Now()
+		chal, err := runSingleRound(llm.ChallengerProvider, llm.ChallengerModel, llm.ChalPrompt, RoleChallenger, tempHistory)
 		if err != nil {
 			return nil, fmt.Errorf("running challenger: %w", err)
 		}
+		chalLatency := time.Since(chalStart)
 		chalEntry := Message{Role: RoleChallenger, Content: chal}
 
 		tempHistory = append(tempHistory, chalEntry)
 		localHistory = append(localHistory, chalEntry)
 
-		_, err = logDebate("Challenger responded: %s", chal)
-		if err != nil {
-			return nil, fmt.Errorf("logging challenger response: %w", err)
-		}
-
-		def, err := runSingleRound(client, llm.DefenderModel, RoleDefender+"\n"+llm.DefPrompt, tempHistory)
+		defStart := time.Now()
+		def, err := runSingleRound(llm.DefenderProvider, llm.DefenderModel, llm.DefPrompt, RoleDefender, tempHistory)
 		if err != nil {
 			return nil, fmt.Errorf("running defender: %w", err)
 		}
+		defLatency := time.Since(defStart)
 		defEntry := Message{Role: RoleDefender, Content: def}
 
 		tempHistory = append(tempHistory, defEntry)
 		localHistory = append(localHistory, defEntry)
 
-		_, err = logDebate("Defender responded: %s", def)
-		if err != nil {
-			return nil, fmt.Errorf("logging defender response: %w", err)
+		history = append(history, localHistory...)
+		round := TranscriptRound{
+			Challenger: chal,
+			Defender:   def,
+			ChallengerMeta: MessageMeta{
+				Timestamp: chalStart,
+				Model:     llm.ChallengerModel,
+				Provider:  transcript.LLM.ChallengerProvider,
+				TokensIn:  approxTokenCount(fullHistoryText(tempHistory[:len(tempHistory)-2])),
+				TokensOut: approxTokenCount(chal),
+				LatencyMS: chalLatency.Milliseconds(),
+			},
+			DefenderMeta: MessageMeta{
+				Timestamp: defStart,
+				Model:     llm.DefenderModel,
+				Provider:  transcript.LLM.DefenderProvider,
+				TokensIn:  approxTokenCount(fullHistoryText(tempHistory[:len(tempHistory)-1])),
+				TokensOut: approxTokenCount(def),
+				LatencyMS: defLatency.Milliseconds(),
+			},
 		}
 
-		history = append(history, localHistory...)
-		rounds = append(rounds, DebateRound{Challenger: chal, Defender: def})
-	}
-	return rounds, nil
-}
+		logger.Info("round completed", "round", i+1, "role", RoleChallenger,
+			"model", round.ChallengerMeta.Model, "provider", round.ChallengerMeta.Provider,
+			"latency_ms", round.ChallengerMeta.LatencyMS,
+			"tokens_in", round.ChallengerMeta.TokensIn, "tokens_out", round.ChallengerMeta.TokensOut)
+		logger.Info("round completed", "round", i+1, "role", RoleDefender,
+			"model", round.DefenderMeta.Model, "provider", round.DefenderMeta.Provider,
+			"latency_ms", round.DefenderMeta.LatencyMS,
+			"tokens_in", round.DefenderMeta.TokensIn, "tokens_out", round.DefenderMeta.TokensOut)
+
+		if llm.JudgeProvider != nil {
+			judgment, err := runJudge(llm.JudgeProvider, llm.JudgeModel, llm.JudgePrompt, i+1, chal, def, tally)
+			if err != nil {
+				return nil, fmt.Errorf("judging round %d: %w", i+1, err)
+			}
+			round.Judgment = &judgment
+			tally.add(judgment)
+			logger.Debug("round judged", "round", i+1, "challenger_total", judgment.Challenger.Total(), "defender_total", judgment.Defender.Total())
+		}
+
+		transcript.Rounds = append(transcript.Rounds, round)
 
-func trimHistory(history []Message, max int) []Message {
-	if len(history) <= max {
-		return history
+		if checkpointPath != "" {
+			if err := writeTranscriptCheckpoint(checkpointPath, transcript); err != nil {
+				return nil, fmt.Errorf("checkpointing transcript: %w", err)
+			}
+		}
 	}
-	return history[len(history)-max:]
+	return transcript, nil
 }
 
-func runSingleRound(client LLMClient, model, prompt string, history []Message) (string, error) {
-	fullPrompt := ""
+func fullHistoryText(history []Message) string {
+	var b string
 	for _, m := range history {
-		fullPrompt += fmt.Sprintf("%s: %s\n", m.Role, m.Content)
+		b += fmt.Sprintf("%s: %s\n", m.Role, m.Content)
 	}
+	return b
+}
+
+// runSingleRound generates the next message for selfRole (a debater role,
+// RoleJudge, or the role of a summarizing call) given the shared history, via
+// provider's own chat payload translation.
+func runSingleRound(provider Provider, model, systemPrompt, selfRole string, history []Message) (string, error) {
+	return withProgress(func() (string, error) {
+		return provider.Generate(context.Background(), model, systemPrompt, selfRole, history)
+	})
+}
 
-	return client.Generate(context.Background(), model, prompt, fullPrompt)
+// withProgress renders an indeterminate progress bar while fn runs, since
+// vendor HTTP calls don't expose the token-by-token streaming ollama does.
+func withProgress(fn func() (string, error)) (string, error) {
+	done := make(chan struct{})
+	progress := progressbar.NewOptions(-1, progressbar.OptionSetWriter(os.Stderr))
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = progress.Add(1)
+			case <-done:
+				_ = progress.Finish()
+				return
+			}
+		}
+	}()
+	result, err := fn()
+	close(done)
+	return result, err
 }
 
 // ---------------- Summarize -----------------
-func summarizeDebate(client LLMClient, debate []DebateRound, model string) (string, error) {
+// summarizeDebate reads rounds from the transcript rather than any
+// in-memory slice, so a crashed run can still be summarized from its
+// checkpoint via "llmdebate summarize". When judging was enabled it also
+// folds the cumulative rubric scores and rolling winner into the report.
+func summarizeDebate(provider Provider, transcript *Transcript, model string) (string, error) {
 	var fullText string
-	for i, r := range debate {
+	tally := judgeTally{}
+	for i, r := range transcript.Rounds {
 		fullText += fmt.Sprintf("### Round %d\nChallenger: %s\nDefender: %s\n\n", i+1, r.Challenger, r.Defender)
+		if r.Judgment != nil {
+			tally.add(*r.Judgment)
+			fullText += fmt.Sprintf("Judge critique: %s\nRunning totals - Challenger: %d, Defender: %d\n\n",
+				r.Judgment.Critique, tally.ChallengerTotal, tally.DefenderTotal)
+		}
 	}
-	return runSingleRound(client, model, "Summarize the debate: top blind spots, opportunities, deadly assumption.", []Message{{Role: RoleSystem, Content: fullText}})
+
+	summary, err := runSingleRound(provider, model, "Summarize the debate: top blind spots, opportunities, deadly assumption.", RoleSummarizer, []Message{{Role: RoleSystem, Content: fullText}})
+	if err != nil {
+		return "", err
+	}
+
+	if tally.Rounds == 0 {
+		return summary, nil
+	}
+	return fmt.Sprintf("%s\n\n## Judge Verdict\nChallenger total: %d\nDefender total: %d\nRolling winner: %s\n",
+		summary, tally.ChallengerTotal, tally.DefenderTotal, tally.rollingWinner()), nil
 }
 
 // ---------------- Utils -----------------
@@ -282,17 +378,69 @@ func parseFlags(cmd *cobra.Command) (DebateConfig, LLMConfig, error) {
 	}
 	rounds, _ := cmd.Flags().GetInt("rounds")
 	duration, _ := cmd.Flags().GetString("duration")
-	chalModel, _ := cmd.Flags().GetString("challenger")
-	defModel, _ := cmd.Flags().GetString("defender")
+	chalSpec, _ := cmd.Flags().GetString("challenger")
+	defSpec, _ := cmd.Flags().GetString("defender")
 	chalPrompt, _ := cmd.Flags().GetString("challenger-prompt")
 	defPrompt, _ := cmd.Flags().GetString("defender-prompt")
+	resume, _ := cmd.Flags().GetBool("resume")
+	transcriptFormat, _ := cmd.Flags().GetString("transcript-format")
+	judgeSpec, _ := cmd.Flags().GetString("judge")
+	judgePrompt, _ := cmd.Flags().GetString("judge-prompt")
+	contextTokens, _ := cmd.Flags().GetInt("context-tokens")
+	summarizeThreshold, _ := cmd.Flags().GetFloat64("summarize-threshold")
+
+	switch transcriptFormat {
+	case "jsonl", "markdown", "both":
+	default:
+		return DebateConfig{}, LLMConfig{}, fmt.Errorf("invalid --transcript-format %q: must be jsonl, markdown, or both", transcriptFormat)
+	}
+
+	chalProviderName, chalModel := ParseProviderModel(chalSpec)
+	defProviderName, defModel := ParseProviderModel(defSpec)
 
-	config := DebateConfig{Rounds: rounds, Duration: duration, InputFile: input, OutputFile: output}
+	chalProvider, err := NewProvider(chalProviderName)
+	if err != nil {
+		return DebateConfig{}, LLMConfig{}, fmt.Errorf("challenger provider: %w", err)
+	}
+	defProvider, err := NewProvider(defProviderName)
+	if err != nil {
+		return DebateConfig{}, LLMConfig{}, fmt.Errorf("defender provider: %w", err)
+	}
+
+	var judgeProvider Provider
+	var judgeModel string
+	if judgeSpec != "" {
+		judgeProviderName, model := ParseProviderModel(judgeSpec)
+		judgeProvider, err = NewProvider(judgeProviderName)
+		if err != nil {
+			return DebateConfig{}, LLMConfig{}, fmt.Errorf("judge provider: %w", err)
+		}
+		judgeModel = model
+	}
+
+	if contextTokens <= 0 {
+		contextTokens = defaultContextTokens(chalProviderName)
+	}
+	tokenizer := NewTokenizer(chalProviderName, chalModel)
+	contextBudget := NewTokenBudget(tokenizer, contextTokens, summarizeThreshold, func(messages []Message) (string, error) {
+		return runSingleRound(chalProvider, chalModel, "Condense the following prior debate context into one brief paragraph a debater could use to keep arguing.", RoleSystem, messages)
+	})
+
+	config := DebateConfig{
+		Rounds: rounds, Duration: duration, InputFile: input, OutputFile: output,
+		Resume: resume, TranscriptFormat: transcriptFormat,
+	}
 	llm := LLMConfig{
-		ChallengerModel: chalModel,
-		DefenderModel:   defModel,
-		ChalPrompt:      mustLoadPrompt(chalPrompt, "You are the Challenger. Attack ruthlessly:"),
-		DefPrompt:       mustLoadPrompt(defPrompt, "You are the Defender. Represent the user:"),
+		ChallengerProvider: chalProvider,
+		ChallengerModel:    chalModel,
+		DefenderProvider:   defProvider,
+		DefenderModel:      defModel,
+		ChalPrompt:         mustLoadPrompt(chalPrompt, "You are the Challenger. Attack ruthlessly:"),
+		DefPrompt:          mustLoadPrompt(defPrompt, "You are the Defender. Represent the user:"),
+		JudgeProvider:      judgeProvider,
+		JudgeModel:         judgeModel,
+		JudgePrompt:        mustLoadPrompt(judgePrompt, defaultJudgePrompt),
+		ContextBudget:      contextBudget,
 	}
 	return config, llm, nil
 }
@@ -303,7 +451,7 @@ func mustLoadPrompt(path, fallback string) string {
 	}
 	data, err := os.ReadFile(path)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "⚠️ Warning: failed to load prompt %s, using default\n", path)
+		logger.Warn("failed to load prompt, using default", "path", path, "error", err)
 		return fallback
 	}
 	return string(data)
@@ -317,9 +465,9 @@ func loadInput(path string) (string, error) {
 	return string(data), nil
 }
 
-func estimateRounds(client LLMClient, claim string, llm LLMConfig, duration string) (int, error) {
+func estimateRounds(claim string, llm LLMConfig, duration string) (int, error) {
 	start := time.Now()
-	_, err := runDebateFlow(client, claim, DebateConfig{Rounds: 1}, llm)
+	_, err := runDebateFlow(claim, DebateConfig{Rounds: 1}, llm)
 	if err != nil {
 		return 0, err
 	}
@@ -335,6 +483,6 @@ func estimateRounds(client LLMClient, claim string, llm LLMConfig, duration stri
 	if rounds < 1 {
 		rounds = 1
 	}
-	fmt.Printf("Estimated %d rounds (1 round = %v)\n", rounds, elapsed)
+	logger.Info("estimated rounds", "rounds", rounds, "round_duration", elapsed.String())
 	return rounds, nil
 }
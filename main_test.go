@@ -11,41 +11,37 @@ import (
 )
 
 type call struct {
-	model  string
-	system string
-	prompt string
+	model    string
+	system   string
+	selfRole string
+	history  []Message
 }
 
-type fakeLLM struct {
+type fakeProvider struct {
+	name    string
 	calls   []call
-	respond func(model, system, prompt string) (string, error)
+	respond func(model, system string, history []Message) (string, error)
 }
 
-func (f *fakeLLM) Generate(ctx context.Context, model, system, prompt string) (string, error) {
-	f.calls = append(f.calls, call{model: model, system: system, prompt: prompt})
+func (f *fakeProvider) Name() string             { return f.name }
+func (f *fakeProvider) GetUserRole() string      { return "user" }
+func (f *fakeProvider) GetSystemRole() string    { return "system" }
+func (f *fakeProvider) GetAssistantRole() string { return "assistant" }
+
+func (f *fakeProvider) Generate(ctx context.Context, model, system, selfRole string, history []Message) (string, error) {
+	f.calls = append(f.calls, call{model: model, system: system, selfRole: selfRole, history: history})
 	if f.respond != nil {
-		return f.respond(model, system, prompt)
+		return f.respond(model, system, history)
 	}
 	return "ok", nil
 }
 
-func TestTrimHistory(t *testing.T) {
-	h := []Message{}
-	for i := 0; i < 15; i++ {
-		h = append(h, Message{Role: "r", Content: "c"})
-	}
-	out := trimHistory(h, 10)
-	if len(out) != 10 {
-		t.Fatalf("expected 10, got %d", len(out))
-	}
-}
-
-func TestRunSingleRound_AssemblesPromptAndUsesParams(t *testing.T) {
-	fake := &fakeLLM{respond: func(model, system, prompt string) (string, error) {
+func TestRunSingleRound_AssemblesHistoryAndUsesParams(t *testing.T) {
+	fake := &fakeProvider{respond: func(model, system string, history []Message) (string, error) {
 		return "resp", nil
 	}}
 	history := []Message{{Role: RoleUser, Content: "claim"}, {Role: RoleChallenger, Content: "c1"}}
-	res, err := runSingleRound(fake, "model-x", "sys-y", history)
+	res, err := runSingleRound(fake, "model-x", "sys-y", RoleChallenger, history)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -56,59 +52,161 @@ func TestRunSingleRound_AssemblesPromptAndUsesParams(t *testing.T) {
 		t.Fatalf("expected 1 call, got %d", len(fake.calls))
 	}
 	c := fake.calls[0]
-	if c.model != "model-x" || c.system != "sys-y" {
-		t.Fatalf("unexpected model/system: %q / %q", c.model, c.system)
+	if c.model != "model-x" || c.system != "sys-y" || c.selfRole != RoleChallenger {
+		t.Fatalf("unexpected model/system/selfRole: %q / %q / %q", c.model, c.system, c.selfRole)
 	}
-	expectedPrompt := "user: claim\nchallenger: c1\n"
-	if c.prompt != expectedPrompt {
-		t.Fatalf("unexpected prompt. want %q, got %q", expectedPrompt, c.prompt)
+	if len(c.history) != 2 || c.history[0].Content != "claim" || c.history[1].Content != "c1" {
+		t.Fatalf("unexpected history: %#v", c.history)
 	}
 }
 
 func TestRunDebateFlow_Basic(t *testing.T) {
-	fake := &fakeLLM{respond: func(model, system, prompt string) (string, error) {
-		if strings.HasPrefix(system, RoleChallenger) {
+	fake := &fakeProvider{respond: func(model, system string, history []Message) (string, error) {
+		if strings.HasPrefix(system, "mC") {
 			return "chal", nil
 		}
-		if strings.HasPrefix(system, RoleDefender) {
+		if strings.HasPrefix(system, "mD") {
 			return "def", nil
 		}
 		return "?", nil
 	}}
 	cfg := DebateConfig{Rounds: 2}
-	llm := LLMConfig{ChallengerModel: "mC", DefenderModel: "mD", ChalPrompt: "CP", DefPrompt: "DP"}
-	rounds, err := runDebateFlow(fake, "the claim", cfg, llm)
+	llm := LLMConfig{
+		ChallengerProvider: fake, ChallengerModel: "mC",
+		DefenderProvider: fake, DefenderModel: "mD",
+		ChalPrompt: "mC", DefPrompt: "mD",
+		ContextBudget: unlimitedContextBudget(),
+	}
+	transcript, err := runDebateFlow("the claim", cfg, llm)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(rounds) != 2 {
-		t.Fatalf("expected 2 rounds, got %d", len(rounds))
+	if len(transcript.Rounds) != 2 {
+		t.Fatalf("expected 2 rounds, got %d", len(transcript.Rounds))
 	}
-	if rounds[0].Challenger != "chal" || rounds[0].Defender != "def" {
-		t.Fatalf("unexpected round[0]: %+v", rounds[0])
+	if transcript.Rounds[0].Challenger != "chal" || transcript.Rounds[0].Defender != "def" {
+		t.Fatalf("unexpected round[0]: %+v", transcript.Rounds[0])
 	}
 	if len(fake.calls) != 4 {
 		t.Fatalf("expected 4 calls, got %d", len(fake.calls))
 	}
-	if !strings.HasPrefix(fake.calls[0].system, RoleChallenger) || !strings.HasPrefix(fake.calls[1].system, RoleDefender) {
-		t.Fatalf("unexpected call order: %#v %#v", fake.calls[0].system, fake.calls[1].system)
+	if fake.calls[0].selfRole != RoleChallenger || fake.calls[1].selfRole != RoleDefender {
+		t.Fatalf("unexpected call order: %#v %#v", fake.calls[0].selfRole, fake.calls[1].selfRole)
 	}
-	// Third call (round 2 challenger) should include previous chal/def in prompt history
+	// Third call (round 2 challenger) should include previous chal/def in history
 	c3 := fake.calls[2]
-	if strings.Count(c3.prompt, RoleChallenger+":") < 1 || strings.Count(c3.prompt, RoleDefender+":") < 1 {
-		t.Fatalf("expected previous round in history, got: %q", c3.prompt)
+	chalCount, defCount := 0, 0
+	for _, m := range c3.history {
+		if m.Role == RoleChallenger {
+			chalCount++
+		}
+		if m.Role == RoleDefender {
+			defCount++
+		}
+	}
+	if chalCount < 1 || defCount < 1 {
+		t.Fatalf("expected previous round in history, got: %#v", c3.history)
 	}
-	// All prompts should start with the user claim
+	// All calls should carry the user claim as the first history entry
 	for i, c := range fake.calls {
-		if !strings.HasPrefix(c.prompt, RoleUser+": the claim\n") {
-			t.Fatalf("call %d prompt does not start with claim: %q", i, c.prompt)
+		if len(c.history) == 0 || c.history[0].Role != RoleUser || c.history[0].Content != "the claim" {
+			t.Fatalf("call %d history does not start with claim: %#v", i, c.history)
 		}
 	}
 }
 
+func TestRunDebateFlow_ResumesFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out.md")
+	checkpoint := transcriptPath(output)
+
+	llmCfg := TranscriptLLMConfig{ChallengerProvider: "fake", ChallengerModel: "mC", DefenderProvider: "fake", DefenderModel: "mD"}
+	seeded := &Transcript{
+		Claim: "the claim",
+		LLM:   llmCfg,
+		Rounds: []TranscriptRound{
+			{Challenger: "c1", Defender: "d1"},
+		},
+	}
+	if err := writeTranscriptCheckpoint(checkpoint, seeded); err != nil {
+		t.Fatalf("writeTranscriptCheckpoint: %v", err)
+	}
+
+	fake := &fakeProvider{name: "fake", respond: func(model, system string, history []Message) (string, error) {
+		if strings.HasPrefix(system, "mC") {
+			return "c2", nil
+		}
+		if strings.HasPrefix(system, "mD") {
+			return "d2", nil
+		}
+		return "?", nil
+	}}
+	cfg := DebateConfig{Rounds: 2, OutputFile: output, Resume: true}
+	llm := LLMConfig{
+		ChallengerProvider: fake, ChallengerModel: "mC",
+		DefenderProvider: fake, DefenderModel: "mD",
+		ChalPrompt: "mC", DefPrompt: "mD",
+		ContextBudget: unlimitedContextBudget(),
+	}
+
+	transcript, err := runDebateFlow("the claim", cfg, llm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transcript.Rounds) != 2 {
+		t.Fatalf("expected 2 rounds (1 replayed + 1 new), got %d", len(transcript.Rounds))
+	}
+	if transcript.Rounds[0].Challenger != "c1" || transcript.Rounds[0].Defender != "d1" {
+		t.Fatalf("expected round 1 to be replayed from checkpoint unchanged, got %+v", transcript.Rounds[0])
+	}
+	if transcript.Rounds[1].Challenger != "c2" || transcript.Rounds[1].Defender != "d2" {
+		t.Fatalf("expected round 2 to be freshly generated, got %+v", transcript.Rounds[1])
+	}
+	// Only the new round should have hit the provider.
+	if len(fake.calls) != 2 {
+		t.Fatalf("expected 2 calls for the resumed round only, got %d", len(fake.calls))
+	}
+	// The new round's history should include the replayed round 1.
+	firstCallHistory := fake.calls[0].history
+	if len(firstCallHistory) != 3 {
+		t.Fatalf("expected replayed round in history, got %#v", firstCallHistory)
+	}
+}
+
+func TestRunDebateFlow_RejectsResumeWithMismatchedLLMConfig(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out.md")
+	checkpoint := transcriptPath(output)
+
+	seeded := &Transcript{
+		Claim:  "the claim",
+		LLM:    TranscriptLLMConfig{ChallengerProvider: "fake", ChallengerModel: "mC", DefenderProvider: "fake", DefenderModel: "mD"},
+		Rounds: []TranscriptRound{{Challenger: "c1", Defender: "d1"}},
+	}
+	if err := writeTranscriptCheckpoint(checkpoint, seeded); err != nil {
+		t.Fatalf("writeTranscriptCheckpoint: %v", err)
+	}
+
+	fake := &fakeProvider{name: "fake"}
+	cfg := DebateConfig{Rounds: 2, OutputFile: output, Resume: true}
+	llm := LLMConfig{
+		ChallengerProvider: fake, ChallengerModel: "different-model",
+		DefenderProvider: fake, DefenderModel: "mD",
+		ChalPrompt: "mC", DefPrompt: "mD",
+		ContextBudget: unlimitedContextBudget(),
+	}
+
+	if _, err := runDebateFlow("the claim", cfg, llm); err == nil {
+		t.Fatalf("expected error resuming with a different --challenger model")
+	}
+	if len(fake.calls) != 0 {
+		t.Fatalf("expected no provider calls when resume is rejected, got %d", len(fake.calls))
+	}
+}
+
 func TestSummarizeDebate_ComposesText(t *testing.T) {
-	fake := &fakeLLM{respond: func(model, system, prompt string) (string, error) { return "summary", nil }}
-	deb := []DebateRound{{Challenger: "C", Defender: "D"}}
+	fake := &fakeProvider{respond: func(model, system string, history []Message) (string, error) { return "summary", nil }}
+	deb := &Transcript{Rounds: []TranscriptRound{{Challenger: "C", Defender: "D"}}}
 	out, err := summarizeDebate(fake, deb, "m")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -123,8 +221,9 @@ func TestSummarizeDebate_ComposesText(t *testing.T) {
 	if !strings.HasPrefix(c.system, "Summarize the debate:") {
 		t.Fatalf("unexpected system prompt: %q", c.system)
 	}
-	if !strings.Contains(c.prompt, "### Round 1") || !strings.Contains(c.prompt, "Challenger: C") || !strings.Contains(c.prompt, "Defender: D") {
-		t.Fatalf("summary input missing content: %q", c.prompt)
+	if len(c.history) != 1 || !strings.Contains(c.history[0].Content, "### Round 1") ||
+		!strings.Contains(c.history[0].Content, "Challenger: C") || !strings.Contains(c.history[0].Content, "Defender: D") {
+		t.Fatalf("summary input missing content: %#v", c.history)
 	}
 }
 
@@ -144,8 +243,8 @@ func TestParseFlags_Valid(t *testing.T) {
 	_ = cmd.Flags().Set("input", "in.md")
 	_ = cmd.Flags().Set("output", "out.md")
 	_ = cmd.Flags().Set("rounds", "2")
-	_ = cmd.Flags().Set("challenger", "mc")
-	_ = cmd.Flags().Set("defender", "md")
+	_ = cmd.Flags().Set("challenger", "ollama/mc")
+	_ = cmd.Flags().Set("defender", "ollama/md")
 	_ = cmd.Flags().Set("challenger-prompt", chalFile)
 	_ = cmd.Flags().Set("defender-prompt", defFile)
 
@@ -173,6 +272,25 @@ func TestParseFlags_MissingRequired(t *testing.T) {
 	}
 }
 
+func TestParseProviderModel(t *testing.T) {
+	cases := []struct {
+		spec         string
+		wantProvider string
+		wantModel    string
+	}{
+		{"llama3", "ollama", "llama3"},
+		{"ollama/llama3", "ollama", "llama3"},
+		{"openai/gpt-4o-mini", "openai", "gpt-4o-mini"},
+		{"gemini/gemini-1.5-pro", "gemini", "gemini-1.5-pro"},
+	}
+	for _, c := range cases {
+		provider, model := ParseProviderModel(c.spec)
+		if provider != c.wantProvider || model != c.wantModel {
+			t.Fatalf("ParseProviderModel(%q) = (%q, %q), want (%q, %q)", c.spec, provider, model, c.wantProvider, c.wantModel)
+		}
+	}
+}
+
 func TestMustLoadPrompt_FallbackOnMissing(t *testing.T) {
 	out := mustLoadPrompt("/path/does/not/exist", "FB")
 	if out != "FB" {
@@ -196,9 +314,16 @@ func TestLoadInput_FileAndMissing(t *testing.T) {
 }
 
 func TestEstimateRounds_InvalidDuration(t *testing.T) {
-	fake := &fakeLLM{}
-	_, err := estimateRounds(fake, "claim", LLMConfig{}, "not-a-duration")
+	fake := &fakeProvider{}
+	llm := LLMConfig{ChallengerProvider: fake, DefenderProvider: fake, ContextBudget: unlimitedContextBudget()}
+	_, err := estimateRounds("claim", llm, "not-a-duration")
 	if err == nil {
 		t.Fatalf("expected error for invalid duration")
 	}
 }
+
+// unlimitedContextBudget builds a ContextBudget that never condenses, for
+// tests whose short histories don't exercise the trimming path.
+func unlimitedContextBudget() ContextBudget {
+	return NewTokenBudget(WhitespaceTokenizer{}, 1_000_000, 0.8, nil)
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ollama/ollama/api"
+)
+
+// Generation bounds shared by every provider so round pacing, cost, and
+// ContextBudget sizing can all assume short, consistently-sampled replies
+// regardless of which vendor is on either side of the debate.
+const (
+	defaultTemperature = 0.7
+	defaultTopP        = 0.9
+	defaultMaxTokens   = 150
+)
+
+// Provider is a vendor-specific chat backend. Each implementation knows how
+// to translate the shared []Message history into its own chat payload shape
+// and which role names the vendor expects.
+type Provider interface {
+	// Name returns the provider identifier used in --challenger/--defender
+	// flags, e.g. "ollama", "openai", "gemini", "glm".
+	Name() string
+
+	GetUserRole() string
+	GetSystemRole() string
+	GetAssistantRole() string
+
+	// Generate runs one chat completion. selfRole identifies which Message.Role
+	// in history belongs to the speaker being generated for; those messages are
+	// sent as the assistant role, everything else as the user role.
+	Generate(ctx context.Context, model, systemPrompt, selfRole string, history []Message) (string, error)
+}
+
+// ParseProviderModel splits a "provider/model" flag value into its parts.
+// A bare model name (no slash) defaults to the ollama provider for
+// backwards compatibility with existing --challenger/--defender usage.
+func ParseProviderModel(spec string) (provider, model string) {
+	if name, rest, ok := strings.Cut(spec, "/"); ok && name != "" && rest != "" {
+		return name, rest
+	}
+	return "ollama", spec
+}
+
+// NewProvider builds a Provider for the given name, reading its vendor
+// credentials and base URL from the environment.
+func NewProvider(name string) (Provider, error) {
+	switch name {
+	case "ollama":
+		client, err := api.ClientFromEnvironment()
+		if err != nil {
+			return nil, fmt.Errorf("creating ollama client: %w", err)
+		}
+		return NewOllamaProvider(client), nil
+	case "openai":
+		return NewOpenAIProvider()
+	case "gemini":
+		return NewGeminiProvider()
+	case "glm":
+		return NewGLMProvider()
+	default:
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+}
+
+func vendorMessages(p Provider, systemPrompt, selfRole string, history []Message) []Message {
+	msgs := make([]Message, 0, len(history)+1)
+	if systemPrompt != "" {
+		msgs = append(msgs, Message{Role: p.GetSystemRole(), Content: systemPrompt})
+	}
+	for _, m := range history {
+		role := p.GetUserRole()
+		if m.Role == selfRole {
+			role = p.GetAssistantRole()
+		}
+		msgs = append(msgs, Message{Role: role, Content: m.Content})
+	}
+	return msgs
+}
+
+// chatMessage is the OpenAI-compatible chat-completions message shape, used
+// as the wire format for both OpenAIProvider and GLMProvider (GLM speaks the
+// same dialect). Message itself carries no JSON tags since it's also the
+// shared in-process history type, so vendor payloads need their own tagged
+// copy rather than marshaling Message directly.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func chatMessages(msgs []Message) []chatMessage {
+	out := make([]chatMessage, len(msgs))
+	for i, m := range msgs {
+		out[i] = chatMessage{Role: m.Role, Content: m.Content}
+	}
+	return out
+}
+
+// ---------------- Ollama provider -----------------
+
+type OllamaProvider struct {
+	Client *api.Client
+}
+
+func NewOllamaProvider(client *api.Client) *OllamaProvider {
+	return &OllamaProvider{Client: client}
+}
+
+func (o *OllamaProvider) Name() string             { return "ollama" }
+func (o *OllamaProvider) GetUserRole() string      { return "user" }
+func (o *OllamaProvider) GetSystemRole() string    { return "system" }
+func (o *OllamaProvider) GetAssistantRole() string { return "assistant" }
+
+func (o *OllamaProvider) Generate(ctx context.Context, model, systemPrompt, selfRole string, history []Message) (string, error) {
+	msgs := vendorMessages(o, systemPrompt, selfRole, history)
+	apiMsgs := make([]api.Message, len(msgs))
+	for i, m := range msgs {
+		apiMsgs[i] = api.Message{Role: m.Role, Content: m.Content}
+	}
+
+	var result string
+	stream := true
+	err := o.Client.Chat(ctx, &api.ChatRequest{
+		Model:    model,
+		Messages: apiMsgs,
+		Stream:   &stream,
+		Options: map[string]any{
+			"temperature": defaultTemperature,
+			"top_p":       defaultTopP,
+			"max_tokens":  defaultMaxTokens,
+		},
+	}, func(resp api.ChatResponse) error {
+		result += resp.Message.Content
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if result == "" {
+		return "", errors.New("empty response")
+	}
+	return result, nil
+}
+
+// ---------------- OpenAI provider -----------------
+
+type OpenAIProvider struct {
+	APIKey  string
+	BaseURL string
+	HTTP    *http.Client
+}
+
+func NewOpenAIProvider() (*OpenAIProvider, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("OPENAI_API_KEY is not set")
+	}
+	baseURL := os.Getenv("OPENAI_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	return &OpenAIProvider{APIKey: apiKey, BaseURL: baseURL, HTTP: &http.Client{Timeout: 2 * time.Minute}}, nil
+}
+
+func (p *OpenAIProvider) Name() string             { return "openai" }
+func (p *OpenAIProvider) GetUserRole() string      { return "user" }
+func (p *OpenAIProvider) GetSystemRole() string    { return "system" }
+func (p *OpenAIProvider) GetAssistantRole() string { return "assistant" }
+
+func (p *OpenAIProvider) Generate(ctx context.Context, model, systemPrompt, selfRole string, history []Message) (string, error) {
+	msgs := vendorMessages(p, systemPrompt, selfRole, history)
+
+	body, err := json.Marshal(map[string]any{
+		"model":       model,
+		"messages":    chatMessages(msgs),
+		"temperature": defaultTemperature,
+		"top_p":       defaultTopP,
+		"max_tokens":  defaultMaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding openai request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("building openai request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	resp, err := p.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling openai: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading openai response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("openai returned %s: %s", resp.Status, data)
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return "", fmt.Errorf("parsing openai response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", errors.New("openai returned no choices")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
+
+// ---------------- Gemini provider -----------------
+
+// GeminiProvider speaks the Gemini generateContent REST API, which uses
+// "user"/"model" roles instead of OpenAI-style "user"/"assistant" and folds
+// the system prompt into a dedicated systemInstruction field.
+type GeminiProvider struct {
+	APIKey  string
+	BaseURL string
+	HTTP    *http.Client
+}
+
+func NewGeminiProvider() (*GeminiProvider, error) {
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("GEMINI_API_KEY is not set")
+	}
+	baseURL := os.Getenv("GEMINI_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://generativelanguage.googleapis.com/v1beta"
+	}
+	return &GeminiProvider{APIKey: apiKey, BaseURL: baseURL, HTTP: &http.Client{Timeout: 2 * time.Minute}}, nil
+}
+
+func (p *GeminiProvider) Name() string             { return "gemini" }
+func (p *GeminiProvider) GetUserRole() string      { return "user" }
+func (p *GeminiProvider) GetSystemRole() string    { return "system" }
+func (p *GeminiProvider) GetAssistantRole() string { return "model" }
+
+func (p *GeminiProvider) Generate(ctx context.Context, model, systemPrompt, selfRole string, history []Message) (string, error) {
+	type part struct {
+		Text string `json:"text"`
+	}
+	type content struct {
+		Role  string `json:"role"`
+		Parts []part `json:"parts"`
+	}
+
+	var contents []content
+	for _, m := range history {
+		role := p.GetUserRole()
+		if m.Role == selfRole {
+			role = p.GetAssistantRole()
+		}
+		contents = append(contents, content{Role: role, Parts: []part{{Text: m.Content}}})
+	}
+
+	payload := map[string]any{
+		"contents": contents,
+		"generationConfig": map[string]any{
+			"maxOutputTokens": defaultMaxTokens,
+			"temperature":     defaultTemperature,
+			"topP":            defaultTopP,
+		},
+	}
+	if systemPrompt != "" {
+		payload["systemInstruction"] = content{Parts: []part{{Text: systemPrompt}}}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encoding gemini request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", p.BaseURL, model, p.APIKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("building gemini request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling gemini: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading gemini response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gemini returned %s: %s", resp.Status, data)
+	}
+
+	var parsed struct {
+		Candidates []struct {
+			Content struct {
+				Parts []part `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return "", fmt.Errorf("parsing gemini response: %w", err)
+	}
+	if len(parsed.Candidates) == 0 || len(parsed.Candidates[0].Content.Parts) == 0 {
+		return "", errors.New("gemini returned no candidates")
+	}
+	return parsed.Candidates[0].Content.Parts[0].Text, nil
+}
+
+// ---------------- GLM provider -----------------
+
+// GLMProvider speaks Zhipu's OpenAI-compatible chat completions API.
+type GLMProvider struct {
+	APIKey  string
+	BaseURL string
+	HTTP    *http.Client
+}
+
+func NewGLMProvider() (*GLMProvider, error) {
+	apiKey := os.Getenv("GLM_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("GLM_API_KEY is not set")
+	}
+	baseURL := os.Getenv("GLM_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://open.bigmodel.cn/api/paas/v4"
+	}
+	return &GLMProvider{APIKey: apiKey, BaseURL: baseURL, HTTP: &http.Client{Timeout: 2 * time.Minute}}, nil
+}
+
+func (p *GLMProvider) Name() string             { return "glm" }
+func (p *GLMProvider) GetUserRole() string      { return "user" }
+func (p *GLMProvider) GetSystemRole() string    { return "system" }
+func (p *GLMProvider) GetAssistantRole() string { return "assistant" }
+
+func (p *GLMProvider) Generate(ctx context.Context, model, systemPrompt, selfRole string, history []Message) (string, error) {
+	msgs := vendorMessages(p, systemPrompt, selfRole, history)
+
+	body, err := json.Marshal(map[string]any{
+		"model":       model,
+		"messages":    chatMessages(msgs),
+		"temperature": defaultTemperature,
+		"top_p":       defaultTopP,
+		"max_tokens":  defaultMaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding glm request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("building glm request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	resp, err := p.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling glm: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading glm response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("glm returned %s: %s", resp.Status, data)
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return "", fmt.Errorf("parsing glm response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", errors.New("glm returned no choices")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
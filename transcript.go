@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MessageMeta captures the observability fields the request asked for
+// alongside each generated message in a transcript.
+type MessageMeta struct {
+	Timestamp time.Time `json:"timestamp"`
+	Model     string    `json:"model"`
+	Provider  string    `json:"provider"`
+	TokensIn  int       `json:"tokens_in"`
+	TokensOut int       `json:"tokens_out"`
+	LatencyMS int64     `json:"latency_ms"`
+}
+
+// TranscriptRound is one completed debate round plus the metadata for both
+// sides that produced it, and the judge's verdict when judging is enabled.
+type TranscriptRound struct {
+	Challenger     string      `json:"challenger"`
+	Defender       string      `json:"defender"`
+	ChallengerMeta MessageMeta `json:"challenger_meta"`
+	DefenderMeta   MessageMeta `json:"defender_meta"`
+	Judgment       *Judgment   `json:"judgment,omitempty"`
+}
+
+// TranscriptLLMConfig is the serializable subset of LLMConfig: Provider is
+// an interface and can't round-trip through JSON, so we record its name.
+type TranscriptLLMConfig struct {
+	ChallengerProvider string `json:"challenger_provider"`
+	ChallengerModel    string `json:"challenger_model"`
+	DefenderProvider   string `json:"defender_provider"`
+	DefenderModel      string `json:"defender_model"`
+	ChalPrompt         string `json:"chal_prompt"`
+	DefPrompt          string `json:"def_prompt"`
+	JudgeProvider      string `json:"judge_provider,omitempty"`
+	JudgeModel         string `json:"judge_model,omitempty"`
+}
+
+// Transcript is the full record of a debate run: the claim it started from,
+// the LLM configuration used, and every round completed so far.
+type Transcript struct {
+	Claim  string              `json:"claim"`
+	LLM    TranscriptLLMConfig `json:"llm"`
+	Rounds []TranscriptRound   `json:"rounds"`
+}
+
+func transcriptLLMConfig(llm LLMConfig) TranscriptLLMConfig {
+	cfg := TranscriptLLMConfig{
+		ChallengerModel: llm.ChallengerModel,
+		DefenderModel:   llm.DefenderModel,
+		ChalPrompt:      llm.ChalPrompt,
+		DefPrompt:       llm.DefPrompt,
+	}
+	if llm.ChallengerProvider != nil {
+		cfg.ChallengerProvider = llm.ChallengerProvider.Name()
+	}
+	if llm.DefenderProvider != nil {
+		cfg.DefenderProvider = llm.DefenderProvider.Name()
+	}
+	if llm.JudgeProvider != nil {
+		cfg.JudgeProvider = llm.JudgeProvider.Name()
+		cfg.JudgeModel = llm.JudgeModel
+	}
+	return cfg
+}
+
+// jsonlRecord is the shape of every line in a transcript JSONL file: the
+// first line is always a "header" record, one "round" record follows per
+// completed round.
+type jsonlRecord struct {
+	Type            string               `json:"type"`
+	Claim           string               `json:"claim,omitempty"`
+	LLM             *TranscriptLLMConfig `json:"llm,omitempty"`
+	TranscriptRound *TranscriptRound     `json:"round,omitempty"`
+}
+
+// transcriptPath derives the checkpoint path for a given output file, as
+// "<output>.jsonl".
+func transcriptPath(outputFile string) string {
+	return outputFile + ".jsonl"
+}
+
+// writeTranscriptCheckpoint atomically (re)writes the whole transcript as
+// JSONL: write to a temp file in the same directory, then rename over the
+// destination so a crash never leaves a truncated or partially-written file.
+func writeTranscriptCheckpoint(path string, t *Transcript) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".transcript-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating transcript temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	w := bufio.NewWriter(tmp)
+	header := jsonlRecord{Type: "header", Claim: t.Claim, LLM: &t.LLM}
+	if err := writeJSONLine(w, header); err != nil {
+		tmp.Close()
+		return err
+	}
+	for i := range t.Rounds {
+		rec := jsonlRecord{Type: "round", TranscriptRound: &t.Rounds[i]}
+		if err := writeJSONLine(w, rec); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("flushing transcript temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing transcript temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("renaming transcript into place: %w", err)
+	}
+	return nil
+}
+
+func writeJSONLine(w *bufio.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding transcript record: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("writing transcript record: %w", err)
+	}
+	return w.WriteByte('\n')
+}
+
+// loadTranscript reads a transcript JSONL file back into memory. It returns
+// (nil, nil) if the file does not exist, so callers can treat "no checkpoint
+// yet" and "resume from checkpoint" uniformly.
+func loadTranscript(path string) (*Transcript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening transcript: %w", err)
+	}
+	defer f.Close()
+
+	t := &Transcript{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec jsonlRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("parsing transcript line: %w", err)
+		}
+		switch rec.Type {
+		case "header":
+			t.Claim = rec.Claim
+			if rec.LLM != nil {
+				t.LLM = *rec.LLM
+			}
+		case "round":
+			if rec.TranscriptRound != nil {
+				t.Rounds = append(t.Rounds, *rec.TranscriptRound)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading transcript: %w", err)
+	}
+	return t, nil
+}
+
+// llmConfigMismatch reports the first difference between a checkpoint's
+// recorded provider/model configuration and the one the current run was
+// invoked with, or "" if they agree. Resuming with a different configuration
+// is rejected rather than silently adopted, since the checkpoint header is
+// what `llmdebate summarize` later trusts to pick providers.
+func llmConfigMismatch(recorded, current TranscriptLLMConfig) string {
+	switch {
+	case recorded.ChallengerProvider != current.ChallengerProvider || recorded.ChallengerModel != current.ChallengerModel:
+		return fmt.Sprintf("--challenger %s/%s does not match checkpoint's %s/%s", current.ChallengerProvider, current.ChallengerModel, recorded.ChallengerProvider, recorded.ChallengerModel)
+	case recorded.DefenderProvider != current.DefenderProvider || recorded.DefenderModel != current.DefenderModel:
+		return fmt.Sprintf("--defender %s/%s does not match checkpoint's %s/%s", current.DefenderProvider, current.DefenderModel, recorded.DefenderProvider, recorded.DefenderModel)
+	case recorded.JudgeProvider != current.JudgeProvider || recorded.JudgeModel != current.JudgeModel:
+		return fmt.Sprintf("--judge %s/%s does not match checkpoint's %s/%s", current.JudgeProvider, current.JudgeModel, recorded.JudgeProvider, recorded.JudgeModel)
+	default:
+		return ""
+	}
+}
+
+// transcriptHistory replays a transcript's completed rounds back into the
+// []Message history shape runDebateFlow works with, so a resumed run looks
+// identical to one that never stopped.
+func transcriptHistory(t *Transcript) []Message {
+	history := []Message{{Role: RoleUser, Content: t.Claim}}
+	for _, r := range t.Rounds {
+		history = append(history,
+			Message{Role: RoleChallenger, Content: r.Challenger},
+			Message{Role: RoleDefender, Content: r.Defender},
+		)
+	}
+	return history
+}
+
+// approxTokenCount is a crude whitespace-based stand-in for a real
+// tokenizer, used only to populate transcript metadata.
+func approxTokenCount(s string) int {
+	return len(strings.Fields(s))
+}
+
+// renderTranscriptMarkdown formats a transcript as a human-readable
+// round-by-round markdown document, in the same shape summarizeDebate's
+// input text has historically used.
+func renderTranscriptMarkdown(t *Transcript) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Debate Transcript\n\n**Claim:** %s\n\n", t.Claim)
+	for i, r := range t.Rounds {
+		fmt.Fprintf(&b, "### Round %d\nChallenger: %s\nDefender: %s\n\n", i+1, r.Challenger, r.Defender)
+	}
+	return b.String()
+}
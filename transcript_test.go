@@ -0,0 +1,106 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscriptCheckpoint_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.md.jsonl")
+
+	t1 := &Transcript{
+		Claim: "the claim",
+		LLM:   TranscriptLLMConfig{ChallengerProvider: "ollama", ChallengerModel: "mC", DefenderProvider: "ollama", DefenderModel: "mD"},
+		Rounds: []TranscriptRound{
+			{Challenger: "c1", Defender: "d1"},
+		},
+	}
+	if err := writeTranscriptCheckpoint(path, t1); err != nil {
+		t.Fatalf("writeTranscriptCheckpoint: %v", err)
+	}
+
+	loaded, err := loadTranscript(path)
+	if err != nil {
+		t.Fatalf("loadTranscript: %v", err)
+	}
+	if loaded == nil {
+		t.Fatalf("expected transcript, got nil")
+	}
+	if loaded.Claim != "the claim" || len(loaded.Rounds) != 1 {
+		t.Fatalf("unexpected loaded transcript: %+v", loaded)
+	}
+	if loaded.Rounds[0].Challenger != "c1" || loaded.Rounds[0].Defender != "d1" {
+		t.Fatalf("unexpected round: %+v", loaded.Rounds[0])
+	}
+	if loaded.LLM.ChallengerModel != "mC" || loaded.LLM.DefenderModel != "mD" {
+		t.Fatalf("unexpected llm config: %+v", loaded.LLM)
+	}
+}
+
+func TestLoadTranscript_MissingFileReturnsNil(t *testing.T) {
+	dir := t.TempDir()
+	loaded, err := loadTranscript(filepath.Join(dir, "missing.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded != nil {
+		t.Fatalf("expected nil transcript for missing file, got %+v", loaded)
+	}
+}
+
+func TestLLMConfigMismatch(t *testing.T) {
+	base := TranscriptLLMConfig{ChallengerProvider: "ollama", ChallengerModel: "mC", DefenderProvider: "ollama", DefenderModel: "mD"}
+
+	if mismatch := llmConfigMismatch(base, base); mismatch != "" {
+		t.Fatalf("expected no mismatch for identical configs, got %q", mismatch)
+	}
+
+	changedChallenger := base
+	changedChallenger.ChallengerModel = "other"
+	if mismatch := llmConfigMismatch(base, changedChallenger); mismatch == "" {
+		t.Fatalf("expected mismatch when challenger model differs")
+	}
+
+	changedJudge := base
+	changedJudge.JudgeProvider = "openai"
+	changedJudge.JudgeModel = "gpt-4o"
+	if mismatch := llmConfigMismatch(base, changedJudge); mismatch == "" {
+		t.Fatalf("expected mismatch when judge is added")
+	}
+}
+
+func TestRenderTranscriptMarkdown(t *testing.T) {
+	tr := &Transcript{
+		Claim:  "the claim",
+		Rounds: []TranscriptRound{{Challenger: "c1", Defender: "d1"}, {Challenger: "c2", Defender: "d2"}},
+	}
+	md := renderTranscriptMarkdown(tr)
+	if !strings.Contains(md, "# Debate Transcript") || !strings.Contains(md, "**Claim:** the claim") {
+		t.Fatalf("missing header content: %q", md)
+	}
+	if !strings.Contains(md, "### Round 1\nChallenger: c1\nDefender: d1") {
+		t.Fatalf("missing round 1 content: %q", md)
+	}
+	if !strings.Contains(md, "### Round 2\nChallenger: c2\nDefender: d2") {
+		t.Fatalf("missing round 2 content: %q", md)
+	}
+}
+
+func TestTranscriptHistory_ReplaysRounds(t *testing.T) {
+	tr := &Transcript{
+		Claim:  "claim",
+		Rounds: []TranscriptRound{{Challenger: "c1", Defender: "d1"}, {Challenger: "c2", Defender: "d2"}},
+	}
+	history := transcriptHistory(tr)
+	if len(history) != 5 {
+		t.Fatalf("expected 5 messages (claim + 2 rounds), got %d", len(history))
+	}
+	if history[0].Role != RoleUser || history[0].Content != "claim" {
+		t.Fatalf("unexpected first message: %+v", history[0])
+	}
+	if history[1].Role != RoleChallenger || history[1].Content != "c1" {
+		t.Fatalf("unexpected second message: %+v", history[1])
+	}
+}